@@ -0,0 +1,150 @@
+// Copyright 2016, Hariharan Srinath
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/srinathh/funnel/pkg/funnel"
+)
+
+// batchJob is one line of batch-mode input: an output file, its segment
+// percentages and optional title/labels.
+type batchJob struct {
+	line     int
+	outfile  string
+	segments []float64
+	labels   []string
+	title    string
+}
+
+type batchResult struct {
+	job batchJob
+	err error
+}
+
+// parseBatchLine parses one batch-mode line of the form
+// "outfile.png: 100,70,40,10,0" with optional "title=...;labels=..."
+// fields appended after the segment list, separated by ';'.
+func parseBatchLine(lineNo int, line string) (batchJob, error) {
+	outfile, rest, ok := strings.Cut(line, ":")
+	if !ok {
+		return batchJob{}, fmt.Errorf("missing ':' separating output file from segments")
+	}
+
+	job := batchJob{line: lineNo, outfile: strings.TrimSpace(outfile)}
+
+	fields := strings.Split(rest, ";")
+	for _, raw := range strings.Split(fields[0], ",") {
+		d, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return batchJob{}, fmt.Errorf("segment %q could not be interpreted as a number: %s", raw, err)
+		}
+		if d > 100 || d < 0 {
+			return batchJob{}, fmt.Errorf("segment %v must be between 0 and 100", d)
+		}
+		job.segments = append(job.segments, d)
+	}
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return batchJob{}, fmt.Errorf("option %q must be key=value", field)
+		}
+		switch key {
+		case "title":
+			job.title = value
+		case "labels":
+			job.labels = strings.Split(value, ",")
+		default:
+			return batchJob{}, fmt.Errorf("unknown batch option %q", key)
+		}
+	}
+
+	return job, nil
+}
+
+// runBatch reads one funnel description per line from r and renders them
+// concurrently with a bounded pool of workers: a producer goroutine turns
+// each line into a job on the jobs channel, workers consume jobs and
+// render them, and every outcome - success or failure - is reported back
+// over the results channel so one bad line never aborts the batch. It
+// returns the number of lines that succeeded and failed.
+func runBatch(r io.Reader, workers int, opts renderOpts) (succeeded, failed int) {
+	jobs := make(chan batchJob)
+	results := make(chan batchResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- batchResult{job: job, err: renderBatchJob(job, opts)}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	go func() {
+		defer close(jobs)
+
+		scanner := bufio.NewScanner(r)
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			job, err := parseBatchLine(lineNo, line)
+			if err != nil {
+				results <- batchResult{job: batchJob{line: lineNo}, err: err}
+				continue
+			}
+			jobs <- job
+		}
+	}()
+
+	for res := range results {
+		if res.err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "line %d (%s): %s\n", res.job.line, res.job.outfile, res.err)
+			continue
+		}
+		succeeded++
+	}
+
+	return succeeded, failed
+}
+
+// renderBatchJob renders a single batch job to its output file, reusing
+// the same encode logic and canvas options as single-funnel mode.
+func renderBatchJob(job batchJob, opts renderOpts) error {
+	fn := funnel.New(job.segments, job.labels, opts.width, opts.height)
+	fn.Title = job.title
+	opts.apply(fn)
+
+	f, err := os.Create(job.outfile)
+	if err != nil {
+		return err
+	}
+
+	if err := encode(f, job.outfile, fn); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
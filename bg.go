@@ -0,0 +1,94 @@
+// Copyright 2016, Hariharan Srinath
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+
+	"github.com/srinathh/funnel/pkg/funnel"
+)
+
+// renderOpts bundles the rendering settings shared by single-funnel and
+// -batch rendering - the CLI's global flags - so both paths decode the
+// background image, parse the background color and apply -nolabels,
+// -legend, -fontsize and -numberformat exactly the same way.
+type renderOpts struct {
+	width, height int
+	alpha         uint8
+	background    image.Image
+	bgColor       *color.RGBA
+	palette       funnel.Palette
+	orientation   funnel.Orientation
+	reverse       bool
+	showLabels    bool
+	legend        bool
+	fontSize      float64
+	numberFormat  string
+}
+
+// newRenderOpts decodes bgPath (if set) and parses bgColor (if set,
+// "#RRGGBB[AA]" or "transparent") into a renderOpts.
+func newRenderOpts(width, height int, alpha uint8, bgPath, bgColor string, palette funnel.Palette, orientation funnel.Orientation, reverse, showLabels, legend bool, fontSize float64, numberFormat string) (renderOpts, error) {
+	opts := renderOpts{
+		width:        width,
+		height:       height,
+		alpha:        alpha,
+		palette:      palette,
+		orientation:  orientation,
+		reverse:      reverse,
+		showLabels:   showLabels,
+		legend:       legend,
+		fontSize:     fontSize,
+		numberFormat: numberFormat,
+	}
+
+	if bgPath != "" {
+		f, err := os.Open(bgPath)
+		if err != nil {
+			return opts, fmt.Errorf("could not open background image %s: %s", bgPath, err)
+		}
+		defer f.Close()
+
+		img, _, err := image.Decode(f)
+		if err != nil {
+			return opts, fmt.Errorf("could not decode background image %s: %s", bgPath, err)
+		}
+		opts.background = img
+	}
+
+	switch bgColor {
+	case "":
+		// leave opts.bgColor nil; EncodePNG/EncodeJPEG default to opaque white
+	case "transparent":
+		opts.bgColor = &color.RGBA{}
+	default:
+		c, err := funnel.ParseHexColor(bgColor)
+		if err != nil {
+			return opts, fmt.Errorf("invalid -bg-color: %s", err)
+		}
+		opts.bgColor = &c
+	}
+
+	return opts, nil
+}
+
+// apply sets fn's shared fields from opts.
+func (opts renderOpts) apply(fn *funnel.Funnel) {
+	fn.Alpha = &opts.alpha
+	fn.Background = opts.background
+	fn.BGColor = opts.bgColor
+	fn.Orientation = opts.orientation
+	fn.Reverse = opts.reverse
+	fn.ShowLabels = opts.showLabels
+	fn.Legend = opts.legend
+	fn.FontSize = opts.fontSize
+	fn.NumberFormat = opts.numberFormat
+	if opts.palette != nil {
+		fn.SetPalette(opts.palette)
+	}
+}
@@ -6,14 +6,15 @@ package main
 import (
 	"flag"
 	"fmt"
-	"image"
-	"image/color"
-	"image/png"
+	"image/jpeg"
+	"io"
 	"log"
 	"os"
-	"strconv"
+	"path/filepath"
+	"runtime"
+	"strings"
 
-	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/srinathh/funnel/pkg/funnel"
 )
 
 const docString = `
@@ -24,151 +25,130 @@ USAGE: funnel -width [width] -height [height] -out [filename] [entries...]
 PARAMETERS:
 -width [width]      The width of the output image in pixels (default 400)
 -height [height]    The height of the output image pixels (default 600)
--out [filename]     The filename of the output PNG image (default funnel.png)        
-[entries...]        Percentages representing each segment. Max 10 entries.
-                    MUST be between 0 and 100 (inclusive). 
+-out [filename]     The filename of the output image (default funnel.png).
+                    The extension selects the format: .png, .jpg/.jpeg or .svg
+-title [title]      Optional title drawn above the chart
+-labels [labels]    Comma separated labels, one per segment, e.g.
+                    "Visits,Signups,Trials,Paid"
+-nolabels           Disable the label + percentage text drawn in each segment
+-legend             Draw a legend strip to the right of the chart
+-fontsize [size]    Font size in points used for labels (default 14)
+-numberformat [fmt] Printf verb used to format each segment's percentage
+                    (default "%.1f%%")
+-batch              Read one funnel per line from stdin instead of
+                    rendering a single funnel from [entries...]. Each line
+                    has the form "outfile.png: 100,70,40,10,0" with
+                    optional "title=...;labels=..." fields.
+-workers [n]        Number of funnels to render concurrently in -batch
+                    mode (default: number of CPUs)
+-bg [path]          Background image (PNG or JPEG) composited under the
+                    chart; raster output only
+-bg-color [color]   Background fill when -bg is not given: "#RRGGBB",
+                    "#RRGGBBAA" or "transparent" (default: opaque white);
+                    raster output only
+-alpha [0-255]      Transparency applied to every segment color, so the
+                    funnel becomes a semi-transparent overlay (default 255)
+-palette [spec]     Preset name (blues, greens, viridis, material-red,
+                    grayscale, colorblind-safe) or a comma separated list
+                    of "#RRGGBB[AA]" colors (default: blues); colors are
+                    interpolated if there are fewer than segments
+-orientation [dir]  "vertical" (default) or "horizontal"
+-reverse            Mirror the flow so the largest segment is drawn last,
+                    producing a pyramid shape
+[entries...]        Percentages representing each segment, or name=value
+                    pairs such as "Visits=100" to supply labels inline.
+                    Max 10 entries. MUST be between 0 and 100 (inclusive).
 EXAMPLE:
 funnel 100 70 40 10 0
+funnel -title "Signup Funnel" -legend Visits=100 Signups=70 Trials=40 Paid=10
+echo "funnel.png: 100,70,40,10,0;title=Signups" | funnel -batch
+funnel -orientation horizontal -reverse 100 70 40 10 0
 `
 
-var colorpal = []color.RGBA{
-	color.RGBA{13, 71, 161, 255},
-	color.RGBA{21, 101, 192, 255},
-	color.RGBA{25, 118, 210, 255},
-	color.RGBA{30, 136, 229, 255},
-	color.RGBA{33, 150, 243, 255},
-	color.RGBA{66, 165, 245, 255},
-	color.RGBA{100, 181, 246, 255},
-	color.RGBA{144, 202, 249, 255},
-	color.RGBA{187, 222, 251, 255},
-	color.RGBA{227, 242, 253, 255},
-}
-
-func getColorPal(n int) []color.RGBA {
-	switch n {
-	case 1:
-		return []color.RGBA{colorpal[9]}
-	case 2:
-		return []color.RGBA{colorpal[0], colorpal[9]}
-	case 3:
-		return []color.RGBA{colorpal[0], colorpal[4], colorpal[9]}
-	case 4:
-		return []color.RGBA{colorpal[0], colorpal[3], colorpal[6], colorpal[9]}
-	case 5:
-		return []color.RGBA{colorpal[0], colorpal[3], colorpal[5], colorpal[7], colorpal[9]}
-	case 6:
-		return []color.RGBA{colorpal[0], colorpal[2], colorpal[4], colorpal[6], colorpal[8], colorpal[9]}
-	case 7:
-		return []color.RGBA{colorpal[0], colorpal[1], colorpal[3], colorpal[4], colorpal[6], colorpal[8], colorpal[9]}
-	case 8:
-		return []color.RGBA{colorpal[0], colorpal[1], colorpal[3], colorpal[4], colorpal[5], colorpal[6], colorpal[8], colorpal[9]}
-	case 9:
-		return []color.RGBA{colorpal[0], colorpal[1], colorpal[2], colorpal[3], colorpal[4], colorpal[5], colorpal[6], colorpal[8], colorpal[9]}
-	}
-	return colorpal
-}
-
-func parseFunnel(entries []string) ([]float64, error) {
-	ret := []float64{}
-
-	for _, entry := range entries {
-		d, err := strconv.ParseFloat(entry, 64)
-		if err != nil {
-			return nil, fmt.Errorf("Entry %s could not be interpreted as a number: %s", entry, err)
-		}
-
-		if d > 100 || d < 0 {
-			return nil, fmt.Errorf("All entries must be between 100 and 0. Got:%d", d)
-		}
-
-		ret = append(ret, d)
-	}
-
-	if len(ret) == 0 {
-		return nil, fmt.Errorf("Funnel must have at least 1 entry")
-	}
-
-	if len(ret) > len(colorpal) {
-		return nil, fmt.Errorf("We support a max of %d funnel entries.", len(colorpal))
-	}
-
-	return ret, nil
-
-}
-
 func main() {
-	var width, height int
-	var outfile string
+	var width, height, workers, alpha int
+	var outfile, title, labels, numberFormat, bg, bgColor, palette, orientation string
+	var noLabels, legend, batch, reverse bool
+	var fontSize float64
 
 	flag.IntVar(&width, "width", 400, "width of the complete funnel")
 	flag.IntVar(&height, "height", 600, "height of the complete funnel")
-	flag.StringVar(&outfile, "out", "funnel.png", "output file name for the image generated")
+	flag.StringVar(&outfile, "out", "funnel.png", "output file name for the image generated (.png, .jpg/.jpeg or .svg)")
+	flag.StringVar(&title, "title", "", "optional title drawn above the chart")
+	flag.StringVar(&labels, "labels", "", "comma separated labels, one per segment")
+	flag.BoolVar(&noLabels, "nolabels", false, "disable the label + percentage text drawn in each segment")
+	flag.BoolVar(&legend, "legend", false, "draw a legend strip to the right of the chart")
+	flag.Float64Var(&fontSize, "fontsize", 0, "font size in points used for labels (default 14)")
+	flag.StringVar(&numberFormat, "numberformat", "", `printf verb used to format each segment's percentage (default "%.1f%%")`)
+	flag.BoolVar(&batch, "batch", false, "read one funnel per line from stdin instead of rendering a single funnel")
+	flag.IntVar(&workers, "workers", runtime.NumCPU(), "number of funnels to render concurrently in -batch mode")
+	flag.StringVar(&bg, "bg", "", "background image (PNG or JPEG) composited under the chart")
+	flag.StringVar(&bgColor, "bg-color", "", `background fill when -bg is not given: "#RRGGBB", "#RRGGBBAA" or "transparent" (default: opaque white)`)
+	flag.IntVar(&alpha, "alpha", 255, "transparency (0-255) applied to every segment color")
+	flag.StringVar(&palette, "palette", "", "preset name or comma separated #RRGGBB[AA] colors (default: blues)")
+	flag.StringVar(&orientation, "orientation", "vertical", `"vertical" or "horizontal"`)
+	flag.BoolVar(&reverse, "reverse", false, "mirror the flow so the largest segment is drawn last (pyramid mode)")
 	flag.Usage = func() {
 		fmt.Fprintln(os.Stderr, docString)
 	}
 
 	flag.Parse()
 
-	funnel, err := parseFunnel(flag.Args())
-	if err != nil {
-		log.Fatalf("Error reading funnel entries: %s", err)
+	if alpha < 0 || alpha > 255 {
+		log.Fatalf("Error: -alpha must be between 0 and 255")
 	}
 
-	skipLast := false
-	if funnel[len(funnel)-1] == 0 {
-		skipLast = true
+	var resolvedPalette funnel.Palette
+	if palette != "" {
+		p, err := resolvePalette(palette)
+		if err != nil {
+			log.Fatal(err)
+		}
+		resolvedPalette = p
 	}
 
-	dest := image.NewRGBA(image.Rect(0, 0, width, height))
-	gc := draw2dimg.NewGraphicContext(dest)
-	verticalDelta := float64(height) / float64(len(funnel))
-	if skipLast {
-		verticalDelta = float64(height) / float64(len(funnel)-1)
+	orient, err := resolveOrientation(orientation)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	colorPal := getColorPal(len(funnel))
-	if skipLast {
-		colorPal = getColorPal(len(funnel) - 1)
+	opts, err := newRenderOpts(width, height, uint8(alpha), bg, bgColor, resolvedPalette, orient, reverse, !noLabels, legend, fontSize, numberFormat)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	for j, funnelVal := range funnel {
-
-		if skipLast && j == len(funnel)-1 {
-			break
+	if batch {
+		if workers < 1 {
+			log.Fatalf("Error: -workers must be at least 1")
 		}
 
-		topY := float64(j) * verticalDelta
-		botY := float64(j+1) * verticalDelta
-		topX := float64(width) * funnelVal / 200
-		botX := topX
-		if j+1 < len(funnel) {
-			botX = float64(width) * funnel[j+1] / 200
+		succeeded, failed := runBatch(os.Stdin, workers, opts)
+		fmt.Fprintf(os.Stderr, "funnel: %d succeeded, %d failed\n", succeeded, failed)
+		if failed > 0 {
+			os.Exit(1)
 		}
+		return
+	}
 
-		gc.SetFillColor(colorPal[j])
-		gc.SetStrokeColor(colorPal[j])
-		gc.MoveTo(float64(width)/2-topX, topY)
-		gc.LineTo(float64(width)/2+topX, topY)
-		gc.LineTo(float64(width)/2+botX, botY)
-		gc.LineTo(float64(width)/2-botX, botY)
-		gc.LineTo(float64(width)/2-topX, topY)
-
-		gc.Close()
-		gc.FillStroke()
+	segments, entryLabels, err := funnel.ParseEntries(flag.Args())
+	if err != nil {
+		log.Fatalf("Error reading funnel entries: %s", err)
 	}
 
-	//gc.SetLineWidth(5)
+	if labels != "" {
+		entryLabels = strings.Split(labels, ",")
+	}
 
-	// Save to file
-	//	draw2dimg.SaveToPngFile("hello.png", dest)
+	fn := funnel.New(segments, entryLabels, width, height)
+	fn.Title = title
+	opts.apply(fn)
 
 	f, err := os.Create(outfile)
 	if err != nil {
-
 		log.Fatal(err)
 	}
 
-	if err := png.Encode(f, dest); err != nil {
+	if err := encode(f, outfile, fn); err != nil {
 		f.Close()
 		log.Fatal(err)
 	}
@@ -176,5 +156,16 @@ func main() {
 	if err := f.Close(); err != nil {
 		log.Fatal(err)
 	}
+}
 
+// encode picks an encoder based on outfile's extension and renders fn to w.
+func encode(w io.Writer, outfile string, fn *funnel.Funnel) error {
+	switch strings.ToLower(filepath.Ext(outfile)) {
+	case ".jpg", ".jpeg":
+		return funnel.EncodeJPEG(w, fn, &jpeg.Options{Quality: jpeg.DefaultQuality})
+	case ".svg":
+		return funnel.EncodeSVG(w, fn)
+	default:
+		return funnel.EncodePNG(w, fn)
+	}
 }
@@ -0,0 +1,22 @@
+// Copyright 2016, Hariharan Srinath
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/srinathh/funnel/pkg/funnel"
+)
+
+// resolveOrientation turns the -orientation flag's value into a
+// funnel.Orientation.
+func resolveOrientation(s string) (funnel.Orientation, error) {
+	switch s {
+	case "vertical":
+		return funnel.Vertical, nil
+	case "horizontal":
+		return funnel.Horizontal, nil
+	default:
+		return funnel.Vertical, fmt.Errorf(`invalid -orientation %q: must be "vertical" or "horizontal"`, s)
+	}
+}
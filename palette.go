@@ -0,0 +1,30 @@
+// Copyright 2016, Hariharan Srinath
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/srinathh/funnel/pkg/funnel"
+)
+
+// resolvePalette turns the -palette flag's value into a funnel.Palette:
+// either a preset name ("blues", "viridis", ...) or a comma separated
+// list of "#RRGGBB[AA]" colors.
+func resolvePalette(spec string) (funnel.Palette, error) {
+	if p, ok := funnel.Presets[spec]; ok {
+		return p, nil
+	}
+
+	var p funnel.Palette
+	for _, hex := range strings.Split(spec, ",") {
+		c, err := funnel.ParseHexColor(strings.TrimSpace(hex))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -palette: %s", err)
+		}
+		p = append(p, c)
+	}
+
+	return p, nil
+}
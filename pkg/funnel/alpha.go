@@ -0,0 +1,34 @@
+package funnel
+
+import "image/color"
+
+// color returns segment j's palette color with f.Alpha applied.
+func (f *Funnel) color(j int) color.RGBA {
+	return scaleAlpha(f.Palette[j], f.alpha())
+}
+
+// alpha returns *f.Alpha, or 255 (fully opaque) if f.Alpha is nil (left
+// unset). f.Alpha is a pointer so an explicit -alpha 0 (fully
+// transparent) stays distinguishable from "unset".
+func (f *Funnel) alpha() uint8 {
+	if f.Alpha == nil {
+		return 255
+	}
+	return *f.Alpha
+}
+
+// scaleAlpha returns c with its alpha channel replaced by alpha, scaling
+// R/G/B to keep the color correctly alpha-premultiplied. c is assumed to
+// already be fully opaque, which holds for every color in this package's
+// palettes.
+func scaleAlpha(c color.RGBA, alpha uint8) color.RGBA {
+	if alpha == 255 {
+		return c
+	}
+	return color.RGBA{
+		R: uint8(uint16(c.R) * uint16(alpha) / 255),
+		G: uint8(uint16(c.G) * uint16(alpha) / 255),
+		B: uint8(uint16(c.B) * uint16(alpha) / 255),
+		A: alpha,
+	}
+}
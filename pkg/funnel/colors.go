@@ -0,0 +1,43 @@
+package funnel
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// ParseHexColor parses a "#RRGGBB" or "#RRGGBBAA" string into a
+// color.RGBA. The alpha channel defaults to fully opaque when omitted.
+func ParseHexColor(s string) (color.RGBA, error) {
+	if len(s) == 0 || s[0] != '#' || (len(s) != 7 && len(s) != 9) {
+		return color.RGBA{}, fmt.Errorf("color %q must be #RRGGBB or #RRGGBBAA", s)
+	}
+
+	var c color.RGBA
+	c.A = 255
+
+	hexByte := func(i int) (uint8, error) {
+		var v uint8
+		if _, err := fmt.Sscanf(s[i:i+2], "%02x", &v); err != nil {
+			return 0, fmt.Errorf("color %q has an invalid hex digit: %s", s, err)
+		}
+		return v, nil
+	}
+
+	var err error
+	if c.R, err = hexByte(1); err != nil {
+		return color.RGBA{}, err
+	}
+	if c.G, err = hexByte(3); err != nil {
+		return color.RGBA{}, err
+	}
+	if c.B, err = hexByte(5); err != nil {
+		return color.RGBA{}, err
+	}
+	if len(s) == 9 {
+		if c.A, err = hexByte(7); err != nil {
+			return color.RGBA{}, err
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,71 @@
+package funnel
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/llgcode/draw2d/draw2dsvg"
+)
+
+// EncodePNG renders f and writes it to w as a PNG image.
+func EncodePNG(w io.Writer, f *Funnel) error {
+	dest := newCanvas(f)
+	gc := draw2dimg.NewGraphicContext(dest)
+	f.Render(gc)
+	return png.Encode(w, dest)
+}
+
+// EncodeJPEG renders f and writes it to w as a JPEG image. o may be nil
+// to use the image/jpeg package defaults.
+func EncodeJPEG(w io.Writer, f *Funnel, o *jpeg.Options) error {
+	dest := newCanvas(f)
+	gc := draw2dimg.NewGraphicContext(dest)
+	f.Render(gc)
+	return jpeg.Encode(w, dest, o)
+}
+
+// EncodeSVG renders f and writes it to w as an SVG document, letting the
+// same geometry that draws PNG/JPEG target a scalable vector backend.
+// f.Background and f.BGColor are raster-only concerns and are ignored
+// here.
+func EncodeSVG(w io.Writer, f *Funnel) error {
+	svg := draw2dsvg.NewSvg()
+	svg.Width = fmt.Sprintf("%dpx", f.Width)
+	svg.Height = fmt.Sprintf("%dpx", f.Height)
+
+	gc := draw2dsvg.NewGraphicContext(svg)
+	f.Render(gc)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(svg)
+}
+
+// newCanvas builds the raster destination for f, compositing
+// f.Background (if set) or filling with f.BGColor (or opaque white, if
+// neither is set) before any segment is drawn on top.
+func newCanvas(f *Funnel) *image.RGBA {
+	dest := image.NewRGBA(image.Rect(0, 0, f.Width, f.Height))
+
+	switch {
+	case f.Background != nil:
+		draw.Draw(dest, dest.Bounds(), f.Background, image.Point{}, draw.Src)
+	case f.BGColor != nil:
+		draw.Draw(dest, dest.Bounds(), &image.Uniform{C: *f.BGColor}, image.Point{}, draw.Src)
+	default:
+		draw.Draw(dest, dest.Bounds(), &image.Uniform{C: color.RGBA{255, 255, 255, 255}}, image.Point{}, draw.Src)
+	}
+
+	return dest
+}
@@ -0,0 +1,30 @@
+package funnel
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+)
+
+// defaultFontTTF is DejaVu Sans, embedded so labels, titles and legends
+// render without requiring callers to install or point at a system font.
+// See assets/DejaVuSans-LICENSE.txt for its license.
+//
+//go:embed assets/DejaVuSans.ttf
+var defaultFontTTF []byte
+
+var defaultFontData = draw2d.FontData{
+	Name:   "funnel-default",
+	Family: draw2d.FontFamilySans,
+	Style:  draw2d.FontStyleNormal,
+}
+
+func init() {
+	font, err := truetype.Parse(defaultFontTTF)
+	if err != nil {
+		panic(fmt.Sprintf("funnel: failed to parse embedded default font: %s", err))
+	}
+	draw2d.RegisterFont(defaultFontData, font)
+}
@@ -0,0 +1,180 @@
+// Package funnel draws scaled funnel graphs against any draw2d graphic
+// context, so the same geometry can be rendered to raster or vector
+// backends.
+// Copyright 2016, Hariharan Srinath
+package funnel
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/llgcode/draw2d"
+)
+
+// Orientation selects which way a Funnel's segments flow.
+type Orientation int
+
+const (
+	// Vertical flows segments top to bottom (the original behaviour).
+	Vertical Orientation = iota
+	// Horizontal flows segments left to right.
+	Horizontal
+)
+
+// Funnel describes a funnel chart: the percentage value of each segment,
+// the pixel dimensions of the canvas and the fill palette used to draw it.
+type Funnel struct {
+	Segments []float64
+	Width    int
+	Height   int
+	Palette  Palette
+	SkipZero bool // true if the final segment is 0 and should not be drawn
+
+	Title        string   // optional title drawn above the chart
+	Labels       []string // optional per-segment labels, same length as Segments
+	ShowLabels   bool     // draw the label + percentage text inside each segment
+	Legend       bool     // draw a legend strip to the right of the chart
+	FontSize     float64  // label font size in points; 0 picks a sensible default
+	NumberFormat string   // fmt verb for the percentage, e.g. "%.1f%%"; "" picks a default
+
+	Background image.Image // optional image composited under the chart; raster output only
+	BGColor    *color.RGBA // fill used when Background is nil; nil picks opaque white, raster output only
+	Alpha      *uint8      // 0-255 applied to every segment color; nil picks the default of fully opaque (255 is a valid, explicit value too, so a bare uint8 can't tell "unset" apart from "fully transparent")
+
+	Orientation Orientation // Vertical (default) or Horizontal
+	Reverse     bool        // mirror the flow so the largest segment is drawn last (pyramid mode)
+}
+
+// New builds a Funnel from segment percentages, optional per-segment
+// labels and canvas dimensions, picking up the default palette and
+// skip-zero behaviour: a trailing 0 segment is kept out of the palette
+// and geometry so funnels that "end" at zero don't draw a degenerate
+// final trapezoid. Labels may be nil; ShowLabels defaults to true
+// whenever labels or a title are wanted, so callers only need to flip it
+// off with -nolabels.
+func New(segments []float64, labels []string, width, height int) *Funnel {
+	skipZero := len(segments) > 0 && segments[len(segments)-1] == 0
+
+	n := len(segments)
+	if skipZero {
+		n--
+	}
+
+	return &Funnel{
+		Segments:   segments,
+		Labels:     labels,
+		Width:      width,
+		Height:     height,
+		Palette:    getColorPal(n),
+		SkipZero:   skipZero,
+		ShowLabels: true,
+	}
+}
+
+// SetPalette overrides f's fill colors with p, expanding or sampling it
+// via PickStops to match the number of segments actually drawn.
+func (f *Funnel) SetPalette(p Palette) {
+	n := len(f.Segments)
+	if f.SkipZero {
+		n--
+	}
+	f.Palette = PickStops(p, n)
+}
+
+// Render draws f's trapezoids, and (when enabled) its title, segment
+// labels and legend, onto gc. gc may be backed by any draw2d context
+// (draw2dimg for raster output, draw2dsvg for vector output) so the same
+// geometry produces PNG, JPEG and SVG alike.
+func (f *Funnel) Render(gc draw2d.GraphicContext) {
+	n := len(f.Segments)
+	if f.SkipZero {
+		n--
+	}
+
+	chartWidth := float64(f.Width)
+	if f.Legend {
+		chartWidth -= legendStripWidth
+	}
+	chartHeight := float64(f.Height)
+
+	titleHeight := 0.0
+	if f.Title != "" {
+		titleHeight = titleBarHeight
+		f.drawTitle(gc, chartWidth)
+		chartHeight -= titleHeight
+	}
+
+	f.drawSegments(gc, n, chartWidth, chartHeight, titleHeight)
+
+	if f.Legend {
+		f.drawLegend(gc, chartWidth, n)
+	}
+}
+
+// drawSegments draws the n trapezoids that make up the funnel body,
+// sharing the same geometry for both orientations: Vertical tapers the
+// segments' X extent down the Y axis, Horizontal does the same with the
+// axes swapped. Reverse mirrors the flow along the primary axis so the
+// largest segment ends up at the far end (bottom, or right), producing a
+// pyramid shape. yOffset shifts the whole body down to make room for a
+// title.
+func (f *Funnel) drawSegments(gc draw2d.GraphicContext, n int, chartWidth, chartHeight, yOffset float64) {
+	horizontal := f.Orientation == Horizontal
+
+	primary := chartHeight
+	if horizontal {
+		primary = chartWidth
+	}
+	delta := primary / float64(n)
+
+	segmentSize := func(idx int) float64 {
+		if horizontal {
+			return chartHeight * f.Segments[idx] / 200
+		}
+		return chartWidth * f.Segments[idx] / 200
+	}
+
+	for i := 0; i < n; i++ {
+		near, far := float64(i)*delta, float64(i+1)*delta
+
+		nearSize := segmentSize(i)
+		farSize := nearSize
+		if i+1 < len(f.Segments) {
+			farSize = segmentSize(i + 1)
+		}
+
+		if f.Reverse {
+			near, far = primary-far, primary-near
+			nearSize, farSize = farSize, nearSize
+		}
+
+		gc.SetFillColor(f.color(i))
+		gc.SetStrokeColor(f.color(i))
+
+		var cx, cy float64
+		if horizontal {
+			cy = yOffset + chartHeight/2
+			cx = (near + far) / 2
+			gc.MoveTo(near, cy-nearSize)
+			gc.LineTo(near, cy+nearSize)
+			gc.LineTo(far, cy+farSize)
+			gc.LineTo(far, cy-farSize)
+			gc.LineTo(near, cy-nearSize)
+		} else {
+			cx = chartWidth / 2
+			cy = yOffset + (near+far)/2
+			gc.MoveTo(cx-nearSize, yOffset+near)
+			gc.LineTo(cx+nearSize, yOffset+near)
+			gc.LineTo(cx+farSize, yOffset+far)
+			gc.LineTo(cx-farSize, yOffset+far)
+			gc.LineTo(cx-nearSize, yOffset+near)
+		}
+
+		gc.Close()
+		gc.FillStroke()
+
+		if f.ShowLabels {
+			f.drawLabel(gc, i, cx, cy)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package funnel
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/llgcode/draw2d"
+)
+
+const (
+	defaultFontSize  = 14.0
+	defaultNumberFmt = "%.1f%%"
+
+	titleFontSize  = 20.0
+	titleBarHeight = 40.0
+
+	legendStripWidth  = 140.0
+	legendSwatchSize  = 16.0
+	legendLineSpacing = 24.0
+	legendPadding     = 12.0
+)
+
+// fontSize returns f.FontSize, or defaultFontSize if it was left unset.
+func (f *Funnel) fontSize() float64 {
+	if f.FontSize == 0 {
+		return defaultFontSize
+	}
+	return f.FontSize
+}
+
+// numberFormat returns f.NumberFormat, or defaultNumberFmt if it was left
+// unset.
+func (f *Funnel) numberFormat() string {
+	if f.NumberFormat == "" {
+		return defaultNumberFmt
+	}
+	return f.NumberFormat
+}
+
+// drawTitle draws f.Title centered above the chart.
+func (f *Funnel) drawTitle(gc draw2d.GraphicContext, chartWidth float64) {
+	gc.SetFillColor(color.RGBA{0, 0, 0, 255})
+	gc.SetFontData(defaultFontData)
+	gc.SetFontSize(titleFontSize)
+	drawCentered(gc, f.Title, chartWidth/2, titleBarHeight/2)
+}
+
+// drawLabel draws segment j's label (if any) and percentage, centered at
+// (cx, cy), picking black or white text depending on the segment's base
+// palette color luminance (not its alpha-scaled fill) so the caption
+// stays legible on both light and dark colors regardless of -alpha.
+func (f *Funnel) drawLabel(gc draw2d.GraphicContext, j int, cx, cy float64) {
+	pct := fmt.Sprintf(f.numberFormat(), f.Segments[j])
+
+	var label string
+	if j < len(f.Labels) {
+		label = f.Labels[j]
+	}
+
+	gc.SetFillColor(textColorFor(f.Palette[j]))
+	gc.SetFontData(defaultFontData)
+	gc.SetFontSize(f.fontSize())
+
+	if label == "" {
+		drawCentered(gc, pct, cx, cy)
+		return
+	}
+
+	lineHeight := f.fontSize() * 1.2
+	drawCentered(gc, label, cx, cy-lineHeight/2)
+	drawCentered(gc, pct, cx, cy+lineHeight/2)
+}
+
+// drawLegend draws a swatch + label for each of the first n segments in
+// the strip to the right of the chart.
+func (f *Funnel) drawLegend(gc draw2d.GraphicContext, chartWidth float64, n int) {
+	x := chartWidth + legendPadding
+	y := legendPadding
+
+	gc.SetFontData(defaultFontData)
+	gc.SetFontSize(f.fontSize())
+
+	for j := 0; j < n; j++ {
+		gc.SetFillColor(f.color(j))
+		gc.SetStrokeColor(f.color(j))
+		gc.MoveTo(x, y)
+		gc.LineTo(x+legendSwatchSize, y)
+		gc.LineTo(x+legendSwatchSize, y+legendSwatchSize)
+		gc.LineTo(x, y+legendSwatchSize)
+		gc.LineTo(x, y)
+		gc.Close()
+		gc.FillStroke()
+
+		label := fmt.Sprintf(f.numberFormat(), f.Segments[j])
+		if j < len(f.Labels) && f.Labels[j] != "" {
+			label = f.Labels[j]
+		}
+
+		gc.SetFillColor(color.RGBA{0, 0, 0, 255})
+		gc.FillStringAt(label, x+legendSwatchSize+6, y+legendSwatchSize*0.75)
+
+		y += legendLineSpacing
+	}
+}
+
+// drawCentered draws text so that it is horizontally and vertically
+// centered on (cx, cy).
+func drawCentered(gc draw2d.GraphicContext, text string, cx, cy float64) {
+	left, top, right, bottom := gc.GetStringBounds(text)
+	gc.FillStringAt(text, cx-(right-left)/2, cy-(bottom-top)/2-top)
+}
+
+// textColorFor picks black or white, whichever is more legible against c,
+// using the standard luminance-weighted RGB formula.
+func textColorFor(c color.RGBA) color.RGBA {
+	luminance := 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+	if luminance > 150 {
+		return color.RGBA{0, 0, 0, 255}
+	}
+	return color.RGBA{255, 255, 255, 255}
+}
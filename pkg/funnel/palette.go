@@ -0,0 +1,99 @@
+package funnel
+
+import "image/color"
+
+// Palette is a named sequence of fill colors for funnel segments. When a
+// Funnel has fewer colors than segments, PickStops interpolates
+// intermediate stops so arbitrary segment counts still get a smooth
+// gradient.
+type Palette []color.RGBA
+
+// PresetBlues is the Material Design blue palette and the default used
+// by New.
+var PresetBlues = Palette{
+	{13, 71, 161, 255},
+	{21, 101, 192, 255},
+	{25, 118, 210, 255},
+	{30, 136, 229, 255},
+	{33, 150, 243, 255},
+	{66, 165, 245, 255},
+	{100, 181, 246, 255},
+	{144, 202, 249, 255},
+	{187, 222, 251, 255},
+	{227, 242, 253, 255},
+}
+
+// PresetGreens is the Material Design green palette.
+var PresetGreens = Palette{
+	{27, 94, 32, 255},
+	{46, 125, 50, 255},
+	{56, 142, 60, 255},
+	{67, 160, 71, 255},
+	{76, 175, 80, 255},
+	{102, 187, 106, 255},
+	{129, 199, 132, 255},
+	{165, 214, 167, 255},
+	{200, 230, 201, 255},
+	{232, 245, 233, 255},
+}
+
+// PresetMaterialRed is the Material Design red palette.
+var PresetMaterialRed = Palette{
+	{183, 28, 28, 255},
+	{211, 47, 47, 255},
+	{229, 57, 53, 255},
+	{239, 83, 80, 255},
+	{244, 67, 54, 255},
+	{229, 115, 115, 255},
+	{239, 154, 154, 255},
+	{255, 205, 210, 255},
+}
+
+// PresetViridis is a subset of the Viridis perceptually-uniform
+// colormap, dark purple to yellow.
+var PresetViridis = Palette{
+	{68, 1, 84, 255},
+	{72, 40, 120, 255},
+	{62, 74, 137, 255},
+	{49, 104, 142, 255},
+	{38, 130, 142, 255},
+	{31, 158, 137, 255},
+	{53, 183, 121, 255},
+	{109, 205, 89, 255},
+	{180, 222, 44, 255},
+	{253, 231, 37, 255},
+}
+
+// PresetGrayscale runs from black to white.
+var PresetGrayscale = Palette{
+	{33, 33, 33, 255},
+	{250, 250, 250, 255},
+}
+
+// PresetColorblindSafe is the Okabe-Ito palette, chosen to stay
+// distinguishable under the common forms of color blindness.
+var PresetColorblindSafe = Palette{
+	{0, 0, 0, 255},
+	{230, 159, 0, 255},
+	{86, 180, 233, 255},
+	{0, 158, 115, 255},
+	{240, 228, 66, 255},
+	{0, 114, 178, 255},
+	{213, 94, 0, 255},
+	{204, 121, 167, 255},
+}
+
+// Presets maps the names accepted by the CLI's -palette flag to their
+// Palette.
+var Presets = map[string]Palette{
+	"blues":           PresetBlues,
+	"greens":          PresetGreens,
+	"viridis":         PresetViridis,
+	"material-red":    PresetMaterialRed,
+	"grayscale":       PresetGrayscale,
+	"colorblind-safe": PresetColorblindSafe,
+}
+
+func getColorPal(n int) []color.RGBA {
+	return PickStops(PresetBlues, n)
+}
@@ -0,0 +1,56 @@
+package funnel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseEntries converts the CLI's positional arguments into segment
+// percentages and, optionally, their labels. Each entry is either a bare
+// percentage ("70") or a "name=value" pair ("Signups=70"); the two forms
+// may not be mixed within a single call.
+func ParseEntries(entries []string) (segments []float64, labels []string, err error) {
+	segments = []float64{}
+	labels = []string{}
+	named := strings.ContainsRune(strings.Join(entries, ""), '=')
+
+	for _, entry := range entries {
+		name, value := "", entry
+		if idx := strings.IndexByte(entry, '='); idx >= 0 {
+			name, value = entry[:idx], entry[idx+1:]
+		} else if named {
+			return nil, nil, fmt.Errorf("Entry %s cannot mix name=value entries with bare percentages", entry)
+		}
+
+		if named && name == "" {
+			return nil, nil, fmt.Errorf("Entry %s must have a name before '='", entry)
+		}
+
+		d, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Entry %s could not be interpreted as a number: %s", entry, err)
+		}
+
+		if d > 100 || d < 0 {
+			return nil, nil, fmt.Errorf("All entries must be between 100 and 0. Got:%v", d)
+		}
+
+		segments = append(segments, d)
+		labels = append(labels, name)
+	}
+
+	if len(segments) == 0 {
+		return nil, nil, fmt.Errorf("Funnel must have at least 1 entry")
+	}
+
+	if len(segments) > len(PresetBlues) {
+		return nil, nil, fmt.Errorf("We support a max of %d funnel entries.", len(PresetBlues))
+	}
+
+	if !named {
+		labels = nil
+	}
+
+	return segments, labels, nil
+}
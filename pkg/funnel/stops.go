@@ -0,0 +1,80 @@
+package funnel
+
+import (
+	"image/color"
+	"math"
+)
+
+// PickStops returns n colors drawn from palette. If palette already has n
+// or more colors, evenly spaced entries from it are used; if it has
+// fewer, intermediate stops are interpolated in linear RGB so arbitrary
+// segment counts still get a smooth gradient.
+func PickStops(palette Palette, n int) []color.RGBA {
+	if n <= 0 {
+		return nil
+	}
+	if len(palette) == 0 {
+		return make([]color.RGBA, n)
+	}
+	if n == 1 {
+		return []color.RGBA{palette[len(palette)/2]}
+	}
+
+	out := make([]color.RGBA, n)
+	for i := 0; i < n; i++ {
+		t := float64(i) / float64(n-1)
+		pos := t * float64(len(palette)-1)
+
+		lo := int(pos)
+		hi := lo + 1
+		if hi >= len(palette) {
+			lo, hi = len(palette)-1, len(palette)-1
+		}
+
+		out[i] = lerpRGBA(palette[lo], palette[hi], pos-float64(lo))
+	}
+	return out
+}
+
+// lerpRGBA interpolates between a and b at t (0..1), blending in linear
+// RGB so the midpoint looks perceptually even rather than muddy.
+func lerpRGBA(a, b color.RGBA, t float64) color.RGBA {
+	return color.RGBA{
+		R: linearToSRGB(lerp(srgbToLinear(a.R), srgbToLinear(b.R), t)),
+		G: linearToSRGB(lerp(srgbToLinear(a.G), srgbToLinear(b.G), t)),
+		B: linearToSRGB(lerp(srgbToLinear(a.B), srgbToLinear(b.B), t)),
+		A: uint8(math.Round(lerp(float64(a.A), float64(b.A), t))),
+	}
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// srgbToLinear converts an 8-bit sRGB component to linear light.
+func srgbToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts a linear-light component back to an 8-bit sRGB
+// value, clamping to [0, 255].
+func linearToSRGB(v float64) uint8 {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 255
+	default:
+		return uint8(math.Round(v * 255))
+	}
+}